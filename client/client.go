@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+// Options specify the options for the DgraphClient.
+type Options struct {
+	// Ignore stores older than this many milliseconds old, when bulk loading.
+	FileBufferSize int
+}
+
+// DefaultOptions is useful if the user doesn't wish to specify any options.
+var DefaultOptions = Options{
+	FileBufferSize: 10000,
+}
+
+// DgraphClient is a stub for the client to talk to Dgraph servers over gRPC. It sends every
+// request over the first connection in conns; the others are not used.
+type DgraphClient struct {
+	conns []*grpc.ClientConn
+	opts  Options
+	dir   string
+
+	// xidIndexOnce guards the one-time alter that ensures xidIndexPredicate is indexed, run
+	// lazily by ResolveXids the first time this client sees an xid-tagged field.
+	xidIndexOnce sync.Once
+}
+
+// NewDgraphClient creates a new DgraphClient which sends its requests over conns[0].
+// clientDir is kept on the client; nothing in this package reads or writes files under it.
+func NewDgraphClient(conns []*grpc.ClientConn, opts Options, clientDir string) *DgraphClient {
+	return &DgraphClient{
+		conns: conns,
+		opts:  opts,
+		dir:   clientDir,
+	}
+}
+
+// Run runs the query and/or mutation embedded in req against the Dgraph cluster and returns
+// the response. If req.SetObject referenced any xid-tagged external ids, they're resolved to
+// existing uids (if any) before the mutation is sent, so the request becomes an upsert.
+func (d *DgraphClient) Run(ctx context.Context, req *Req) (*protos.Response, error) {
+	if err := req.ResolveXids(ctx, d); err != nil {
+		return nil, err
+	}
+	c := protos.NewDgraphClient(d.conns[0])
+	return c.Run(ctx, &req.gr)
+}
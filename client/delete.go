@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// DeleteOpt configures a DeleteSubtree call.
+type DeleteOpt func(*protos.DeleteSubtreeRequest)
+
+// MaxDepth bounds how many hops the server-side BFS will traverse from rootUID before it
+// stops, so a malformed predicate list can't walk the whole graph.
+func MaxDepth(depth uint32) DeleteOpt {
+	return func(r *protos.DeleteSubtreeRequest) {
+		r.MaxDepth = depth
+	}
+}
+
+// DeleteSubtree deletes rootUID and every node reachable from it by following predicates,
+// within a single transaction on the server. It returns the uids that were deleted.
+//
+// Unlike hand-walking a query response and passing the flattened uid list to DeleteObject,
+// DeleteSubtree re-discovers reachable nodes server-side inside the deleting transaction, so
+// it can't race with edges added after a prior query snapshot was taken. The traversal is a
+// BFS from rootUID over predicates, guarded against cycles by a visited-uid set.
+func (d *DgraphClient) DeleteSubtree(ctx context.Context, rootUID uint64, predicates []string, opts ...DeleteOpt) ([]uint64, error) {
+	req := &protos.DeleteSubtreeRequest{
+		RootUid:    rootUID,
+		Predicates: predicates,
+	}
+	for _, opt := range opts {
+		opt(req)
+	}
+	if req.MaxDepth == 0 {
+		req.MaxDepth = defaultMaxDeleteDepth
+	}
+
+	c := protos.NewDgraphClient(d.conns[0])
+	resp, err := c.DeleteSubtree(ctx, req)
+	if err != nil {
+		return nil, x.Wrapf(err, "DeleteSubtree")
+	}
+	return resp.DeletedUids, nil
+}
+
+// defaultMaxDeleteDepth bounds the BFS when the caller doesn't supply MaxDepth.
+const defaultMaxDeleteDepth = 1000
@@ -0,0 +1,261 @@
+package client
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// dgraphTypeTag is the json tag that marks a struct field (or the struct itself, via a
+// `DgraphType string` field carrying this tag) as holding the dgraph.type value for the node.
+const dgraphTypeTag = "dgraph.type"
+
+// Req wraps the query and mutation that make up a single request to Dgraph.
+type Req struct {
+	gr protos.Request
+
+	// blankCount assigns successive blank node labels ("blank-0", "blank-1", ...) to objects
+	// passed to SetObject that don't already carry a uid.
+	blankCount uint64
+
+	// xids collects every external id seen via an `,xid` tagged field while encoding this
+	// request, keyed by the blank label standing in for it. Populated by SetObject, consumed
+	// by ResolveXids.
+	xids map[string]*xidRef
+}
+
+// SetQuery sets a GraphQL+- query string on the request.
+func (req *Req) SetQuery(q string) {
+	req.gr.Query = q
+}
+
+// typeName returns the dgraph.type value for v, if one was supplied either via a struct field
+// tagged `json:"dgraph.type"` or via a registered type name for v's concrete Go type.
+func typeName(v reflect.Value) (string, bool) {
+	if v.Kind() != reflect.Struct {
+		return "", false
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if jsonTag(t.Field(i)) == dgraphTypeTag {
+			s, ok := v.Field(i).Interface().(string)
+			if ok && s != "" {
+				return s, true
+			}
+		}
+	}
+	if name, ok := typeNameForGoType(t); ok {
+		return name, true
+	}
+	return "", false
+}
+
+func jsonTag(f reflect.StructField) string {
+	tag := f.Tag.Get("json")
+	for i := 0; i < len(tag); i++ {
+		if tag[i] == ',' {
+			return tag[:i]
+		}
+	}
+	return tag
+}
+
+// fieldOptions returns a field's predicate name and whether its json tag carries the xid
+// option, e.g. `json:"author_id,xid"`.
+func fieldOptions(f reflect.StructField) (pred string, isXid bool) {
+	tag := f.Tag.Get("json")
+	parts := strings.Split(tag, ",")
+	pred = parts[0]
+	for _, opt := range parts[1:] {
+		if opt == xidOption {
+			isXid = true
+		}
+	}
+	return pred, isXid
+}
+
+// SetObject adds the mutation obtained by converting v into NQuads. v must be a pointer to, or
+// a value of, a struct (or slice of structs) whose fields carry `json` tags naming predicates.
+// Every blank node created for v (and its nested objects) is assigned the object's dgraph.type,
+// if one is present, so that a subsequent type(...) filter or expand(_all_) works against it.
+func (req *Req) SetObject(v interface{}) error {
+	val := reflect.Indirect(reflect.ValueOf(v))
+	if val.Kind() == reflect.Slice {
+		for i := 0; i < val.Len(); i++ {
+			if err := req.SetObject(val.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	_, nquads, err := req.nquadsForObject(val, "")
+	if err != nil {
+		return err
+	}
+	req.gr.Mutation.Set = append(req.gr.Mutation.Set, nquads...)
+	return nil
+}
+
+// DeleteObject adds a delete mutation for v, which must carry a non-zero uid (via a
+// `json:"_uid_"` field) for every object in the slice.
+func (req *Req) DeleteObject(v interface{}) error {
+	val := reflect.Indirect(reflect.ValueOf(v))
+	if val.Kind() != reflect.Slice {
+		val = reflect.ValueOf([]interface{}{v})
+	}
+	for i := 0; i < val.Len(); i++ {
+		item := reflect.Indirect(val.Index(i))
+		uid, ok := uidOf(item)
+		if !ok || uid == 0 {
+			return x.Errorf("DeleteObject: item %d has no uid set", i)
+		}
+		req.gr.Mutation.Del = append(req.gr.Mutation.Del, &protos.NQuad{
+			Subject:     toSubject(uid),
+			Predicate:   "_predicate_",
+			ObjectValue: nil,
+		})
+	}
+	return nil
+}
+
+func uidOf(v reflect.Value) (uint64, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if jsonTag(t.Field(i)) == "_uid_" {
+			return v.Field(i).Uint(), true
+		}
+	}
+	return 0, false
+}
+
+func toSubject(uid uint64) string {
+	return fmt.Sprintf("<%#x>", uid)
+}
+
+// nextBlank returns the next unused blank node label for this request, matching the
+// "blank-0", "blank-1", ... numbering returned in Response.AssignedUids.
+func (req *Req) nextBlank() string {
+	n := atomic.AddUint64(&req.blankCount, 1) - 1
+	return "_:blank-" + strconv.FormatUint(n, 10)
+}
+
+// nquadsForObject walks v (following nested structs and slices of structs) emitting one NQuad
+// per non-empty field, plus a dgraph.type NQuad for every blank node whose type is known. It
+// returns v's own resolved subject (a uid or blank node label) alongside the NQuads, so a
+// caller embedding v as a nested object can link to it with an edge NQuad.
+func (req *Req) nquadsForObject(v reflect.Value, subject string) (string, []*protos.NQuad, error) {
+	v = reflect.Indirect(v)
+	if v.Kind() != reflect.Struct {
+		return "", nil, x.Errorf("SetObject: expected struct, got %s", v.Kind())
+	}
+
+	if subject == "" {
+		if uid, ok := uidOf(v); ok && uid != 0 {
+			subject = toSubject(uid)
+		} else {
+			subject = req.nextBlank()
+		}
+	}
+
+	var nquads []*protos.NQuad
+	if name, ok := typeName(v); ok {
+		nquads = append(nquads, &protos.NQuad{
+			Subject:   subject,
+			Predicate: dgraphTypeTag,
+			ObjectValue: &protos.Value{
+				Val: &protos.Value_StrVal{StrVal: name},
+			},
+		})
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		pred, isXid := fieldOptions(t.Field(i))
+		if pred == "" || pred == "_uid_" || pred == dgraphTypeTag {
+			continue
+		}
+		fv := v.Field(i)
+		if isXid {
+			xidVal, ok := fv.Interface().(string)
+			if !ok || xidVal == "" {
+				continue
+			}
+			blank, created := req.xidBlank(xidVal)
+			if created {
+				nquads = append(nquads, &protos.NQuad{
+					Subject:   blank,
+					Predicate: xidIndexPredicate,
+					ObjectValue: &protos.Value{
+						Val: &protos.Value_StrVal{StrVal: xidVal},
+					},
+				})
+			}
+			nquads = append(nquads, &protos.NQuad{
+				Subject:   subject,
+				Predicate: pred,
+				ObjectId:  blank,
+			})
+			continue
+		}
+		switch fv.Kind() {
+		case reflect.Slice:
+			for j := 0; j < fv.Len(); j++ {
+				childSubject, child, err := req.nquadsForObject(fv.Index(j), "")
+				if err != nil {
+					return "", nil, err
+				}
+				nquads = append(nquads, &protos.NQuad{
+					Subject:   subject,
+					Predicate: pred,
+					ObjectId:  childSubject,
+				})
+				nquads = append(nquads, child...)
+			}
+		case reflect.Struct:
+			childSubject, child, err := req.nquadsForObject(fv, "")
+			if err != nil {
+				return "", nil, err
+			}
+			nquads = append(nquads, &protos.NQuad{
+				Subject:   subject,
+				Predicate: pred,
+				ObjectId:  childSubject,
+			})
+			nquads = append(nquads, child...)
+		default:
+			if isZero(fv) {
+				continue
+			}
+			nquads = append(nquads, &protos.NQuad{
+				Subject:     subject,
+				Predicate:   pred,
+				ObjectValue: valueFor(fv),
+			})
+		}
+	}
+	return subject, nquads, nil
+}
+
+func isZero(v reflect.Value) bool {
+	return v.Interface() == reflect.Zero(v.Type()).Interface()
+}
+
+func valueFor(v reflect.Value) *protos.Value {
+	switch v.Kind() {
+	case reflect.String:
+		return &protos.Value{Val: &protos.Value_StrVal{StrVal: v.String()}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &protos.Value{Val: &protos.Value_IntVal{IntVal: v.Int()}}
+	case reflect.Bool:
+		return &protos.Value{Val: &protos.Value_BoolVal{BoolVal: v.Bool()}}
+	case reflect.Float32, reflect.Float64:
+		return &protos.Value{Val: &protos.Value_DoubleVal{DoubleVal: v.Float()}}
+	default:
+		return &protos.Value{Val: &protos.Value_StrVal{StrVal: fmt.Sprintf("%v", v.Interface())}}
+	}
+}
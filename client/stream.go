@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"io"
+	"reflect"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// ResultStream delivers a query's result tree one root child at a time, instead of requiring
+// the client to receive and buffer a single response holding the whole tree before any of it
+// can be decoded. The server still evaluates the whole SubGraph before the first child is
+// sent (see RunStream).
+type ResultStream interface {
+	// Recv returns the next root child node, or io.EOF once the server has sent every one.
+	Recv() (*protos.Node, error)
+}
+
+type resultStream struct {
+	stream protos.Dgraph_RunStreamClient
+}
+
+func (r *resultStream) Recv() (*protos.Node, error) {
+	sr, err := r.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if sr.Done {
+		return nil, io.EOF
+	}
+	return sr.N, nil
+}
+
+// RunStream sends req the same way Run does (including resolving any xid-tagged fields from
+// SetObject into upserts), but returns a ResultStream that yields one root child at a time
+// instead of a single protos.Response holding the whole result tree. The win is client-side:
+// the caller (directly, or via Decoder) can start acting on the first Thread in a forum query
+// without waiting for or buffering the rest. The server still evaluates req.Query to
+// completion before sending the first StreamResponse - see query.StreamChildren - so this
+// doesn't reduce server-side memory use or latency to the first result, only client-side
+// buffering once evaluation is done.
+func (d *DgraphClient) RunStream(ctx context.Context, req *Req) (ResultStream, error) {
+	if err := req.ResolveXids(ctx, d); err != nil {
+		return nil, err
+	}
+	c := protos.NewDgraphClient(d.conns[0])
+	stream, err := c.RunStream(ctx, &req.gr)
+	if err != nil {
+		return nil, x.Wrapf(err, "RunStream")
+	}
+	return &resultStream{stream: stream}, nil
+}
+
+// Decoder incrementally fills a user struct from a ResultStream, calling a callback as each
+// top-level child (e.g. each Thread in a forum query) arrives, instead of requiring the
+// caller to wait for client.Unmarshal on the full response.
+type Decoder struct {
+	stream ResultStream
+}
+
+// NewDecoder returns a Decoder reading from stream.
+func NewDecoder(stream ResultStream) *Decoder {
+	return &Decoder{stream: stream}
+}
+
+// Decode reads from the underlying stream until it's exhausted, filling v (a pointer to a
+// struct tagged the same way client.Unmarshal expects) and invoking onChild, if non-nil, with
+// a pointer to each slice element as it's appended. onChild returning an error aborts Decode.
+func (dec *Decoder) Decode(v interface{}, onChild func(child interface{}) error) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return x.Errorf("Decode: v must be a non-nil pointer")
+	}
+	root := reflect.Indirect(rv)
+	t := root.Type()
+
+	for {
+		n, err := dec.stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		matched := false
+		for i := 0; i < t.NumField(); i++ {
+			if jsonTag(t.Field(i)) != n.Attribute {
+				continue
+			}
+			fv := root.Field(i)
+			if fv.Kind() != reflect.Slice {
+				break
+			}
+			elem := reflect.New(fv.Type().Elem()).Elem()
+			if err := unmarshalStruct([]*protos.Node{n}, elem); err != nil {
+				return err
+			}
+			fv.Set(reflect.Append(fv, elem))
+			matched = true
+			if onChild != nil {
+				if err := onChild(fv.Index(fv.Len() - 1).Addr().Interface()); err != nil {
+					return err
+				}
+			}
+			break
+		}
+		if !matched {
+			// A node whose attribute doesn't name one of v's slice fields carries the root's
+			// own scalar predicates (e.g. the forum's name and description). Wrapping it lets
+			// unmarshalStruct find it as the child named by its own attribute.
+			if err := unmarshalStruct([]*protos.Node{{Children: []*protos.Node{n}}}, root); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+type testPost struct {
+	Id         uint64 `json:"_uid_,omitempty"`
+	DgraphType string `json:"dgraph.type,omitempty"`
+	Title      string `json:"title,omitempty"`
+}
+
+type testThread struct {
+	Id         uint64     `json:"_uid_,omitempty"`
+	DgraphType string     `json:"dgraph.type,omitempty"`
+	Title      string     `json:"title,omitempty"`
+	Posts      []testPost `json:"posts,omitempty"`
+}
+
+type testForum struct {
+	Id         uint64       `json:"_uid_,omitempty"`
+	DgraphType string       `json:"dgraph.type,omitempty"`
+	Name       string       `json:"name,omitempty"`
+	Threads    []testThread `json:"threads,omitempty"`
+}
+
+// findNQuad returns the first NQuad in nquads matching subject and predicate, if any.
+func findNQuad(nquads []*protos.NQuad, subject, pred string) *protos.NQuad {
+	for _, nq := range nquads {
+		if nq.Subject == subject && nq.Predicate == pred {
+			return nq
+		}
+	}
+	return nil
+}
+
+// TestSetObjectLinksNestedObjects ensures SetObject emits an edge NQuad from a parent to every
+// nested struct and slice-of-struct field, not just the nested object's own NQuads. Without
+// this edge, type(Forum)/expand(_all_) traversal from the forum never reaches its threads.
+func TestSetObjectLinksNestedObjects(t *testing.T) {
+	f := testForum{
+		DgraphType: "Forum",
+		Name:       "My forum",
+		Threads: []testThread{
+			{
+				DgraphType: "Thread",
+				Title:      "How to build an App?",
+				Posts: []testPost{
+					{DgraphType: "Post", Title: "Using the Go client"},
+				},
+			},
+		},
+	}
+
+	req := &Req{}
+	forumSubject, nquads, err := req.nquadsForObject(reflect.ValueOf(f), "")
+	if err != nil {
+		t.Fatalf("nquadsForObject: %v", err)
+	}
+
+	threadEdge := findNQuad(nquads, forumSubject, "threads")
+	if threadEdge == nil || threadEdge.ObjectId == "" {
+		t.Fatalf("expected a threads edge from %s, got nquads: %+v", forumSubject, nquads)
+	}
+
+	threadSubject := threadEdge.ObjectId
+	if findNQuad(nquads, threadSubject, "dgraph.type") == nil {
+		t.Fatalf("expected a dgraph.type nquad for thread %s", threadSubject)
+	}
+
+	postEdge := findNQuad(nquads, threadSubject, "posts")
+	if postEdge == nil || postEdge.ObjectId == "" {
+		t.Fatalf("expected a posts edge from %s, got nquads: %+v", threadSubject, nquads)
+	}
+	if findNQuad(nquads, postEdge.ObjectId, "title") == nil {
+		t.Fatalf("expected a title nquad for post %s", postEdge.ObjectId)
+	}
+}
+
+// TestUnmarshalRoutesByRegisteredType exercises the dgraph.type -> Go type round trip:
+// RegisterType lets Unmarshal decode a polymorphic result array into the right concrete type.
+func TestUnmarshalRoutesByRegisteredType(t *testing.T) {
+	RegisterType("testThread", testThread{})
+
+	nodes := []*protos.Node{
+		{
+			Attribute: "threads",
+			Children: []*protos.Node{
+				{
+					Attribute: "dgraph.type",
+					Properties: []*protos.Property{
+						{Value: &protos.Value{Val: &protos.Value_StrVal{StrVal: "testThread"}}},
+					},
+				},
+				{
+					Attribute: "title",
+					Properties: []*protos.Property{
+						{Value: &protos.Value{Val: &protos.Value_StrVal{StrVal: "Hello"}}},
+					},
+				},
+			},
+		},
+	}
+
+	var threads []testThread
+	if err := unmarshalInto(nodes, reflect.ValueOf(&threads).Elem()); err != nil {
+		t.Fatalf("unmarshalInto: %v", err)
+	}
+	if len(threads) != 1 {
+		t.Fatalf("expected 1 thread, got %d", len(threads))
+	}
+	if threads[0].Title != "Hello" {
+		t.Fatalf("expected title %q, got %q", "Hello", threads[0].Title)
+	}
+	if threads[0].DgraphType != "testThread" {
+		t.Fatalf("expected dgraph.type %q, got %q", "testThread", threads[0].DgraphType)
+	}
+}
+
+// TestUnmarshalIgnoresUnassignableRegisteredType ensures a node whose dgraph.type resolves to
+// a registered type that doesn't fit the slice element type is decoded as the slice's own
+// element type instead of panicking inside reflect.Append with a mismatched type.
+func TestUnmarshalIgnoresUnassignableRegisteredType(t *testing.T) {
+	RegisterType("testThread", testThread{})
+
+	nodes := []*protos.Node{
+		{
+			Attribute: "posts",
+			Children: []*protos.Node{
+				{
+					Attribute: "dgraph.type",
+					Properties: []*protos.Property{
+						{Value: &protos.Value{Val: &protos.Value_StrVal{StrVal: "testThread"}}},
+					},
+				},
+				{
+					Attribute: "title",
+					Properties: []*protos.Property{
+						{Value: &protos.Value{Val: &protos.Value_StrVal{StrVal: "Hello"}}},
+					},
+				},
+			},
+		},
+	}
+
+	var posts []testPost
+	if err := unmarshalInto(nodes, reflect.ValueOf(&posts).Elem()); err != nil {
+		t.Fatalf("unmarshalInto: %v", err)
+	}
+	if len(posts) != 1 {
+		t.Fatalf("expected 1 post, got %d", len(posts))
+	}
+	if posts[0].Title != "Hello" {
+		t.Fatalf("expected title %q, got %q", "Hello", posts[0].Title)
+	}
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// typeRegistry maps a dgraph.type name to the concrete Go type that Unmarshal should decode
+// a node carrying that type into, for slices whose element type the registered type is
+// assignable to (an interface it implements, or the type itself). Single struct or interface
+// fields are not routed through it. Registered via RegisterType.
+var typeRegistry = make(map[string]reflect.Type)
+
+// goTypeRegistry is the inverse of typeRegistry: it lets SetObject look up the dgraph.type
+// name for a struct that didn't set one explicitly via a `json:"dgraph.type"` field.
+var goTypeRegistry = make(map[reflect.Type]string)
+
+// RegisterType associates name (the value of the dgraph.type predicate on a node) with the
+// concrete type of sample, so that Unmarshal can route a node carrying that type into a Go
+// value of the right shape when decoding a polymorphic result array, and so that SetObject
+// can tag blank nodes created from values of that type without an explicit DgraphType field.
+//
+//	client.RegisterType("Forum", Forum{})
+func RegisterType(name string, sample interface{}) {
+	t := reflect.TypeOf(sample)
+	typeRegistry[name] = t
+	goTypeRegistry[t] = name
+}
+
+// typeNameForGoType looks up the dgraph.type name registered for t via RegisterType.
+func typeNameForGoType(t reflect.Type) (string, bool) {
+	name, ok := goTypeRegistry[t]
+	return name, ok
+}
+
+// Unmarshal decodes the result nodes of a query (resp.N) into v, which must be a pointer to a
+// struct whose fields are tagged with the predicate names used in the query. Fields tagged
+// `json:"dgraph.type"` are populated with the node's dgraph.type value, if any.
+func Unmarshal(nodes []*protos.Node, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return x.Errorf("Unmarshal: v must be a non-nil pointer")
+	}
+	return unmarshalInto(nodes, rv.Elem())
+}
+
+func unmarshalInto(nodes []*protos.Node, v reflect.Value) error {
+	v = reflect.Indirect(v)
+	switch v.Kind() {
+	case reflect.Struct:
+		return unmarshalStruct(nodes, v)
+	case reflect.Slice:
+		for _, n := range nodes {
+			elemType := v.Type().Elem()
+			if resolved, ok := typeForNode(n, elemType); ok {
+				elemType = resolved
+			}
+			elem := reflect.New(elemType).Elem()
+			if err := unmarshalStruct([]*protos.Node{n}, elem); err != nil {
+				return err
+			}
+			v.Set(reflect.Append(v, elem))
+		}
+	default:
+		if len(nodes) == 0 || len(nodes[0].Properties) == 0 {
+			return nil
+		}
+		return setScalar(v, nodes[0].Properties[0].Value)
+	}
+	return nil
+}
+
+// setScalar assigns val's underlying scalar (string, int, bool or float) to v. A uid field
+// (e.g. `json:"_uid_"`, typed uint64) arrives as a string like "0x1" and is parsed accordingly.
+func setScalar(v reflect.Value, val *protos.Value) error {
+	switch x := val.Val.(type) {
+	case *protos.Value_StrVal:
+		switch v.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			uid, err := strconv.ParseUint(x.StrVal, 0, 64)
+			if err != nil {
+				return err
+			}
+			v.SetUint(uid)
+		default:
+			v.SetString(x.StrVal)
+		}
+	case *protos.Value_IntVal:
+		v.SetInt(x.IntVal)
+	case *protos.Value_BoolVal:
+		v.SetBool(x.BoolVal)
+	case *protos.Value_DoubleVal:
+		v.SetFloat(x.DoubleVal)
+	}
+	return nil
+}
+
+// typeForNode looks up n's dgraph.type attribute (if present among its children) in the
+// type registry, returning the registered concrete type when it's assignable to fallback. A
+// registered type that doesn't satisfy fallback (e.g. a result array mixing types that were
+// never meant to share a slice) is reported as not found rather than handed back, so the
+// caller keeps decoding into fallback instead of appending a mismatched type into it.
+func typeForNode(n *protos.Node, fallback reflect.Type) (reflect.Type, bool) {
+	name := dgraphTypeOf(n)
+	if name == "" {
+		return nil, false
+	}
+	t, ok := typeRegistry[name]
+	if !ok || !t.AssignableTo(fallback) {
+		return nil, false
+	}
+	return t, true
+}
+
+// dgraphTypeOf scans n's children for the dgraph.type predicate value emitted by SetObject.
+func dgraphTypeOf(n *protos.Node) string {
+	for _, child := range n.Children {
+		if child.Attribute == dgraphTypeTag && len(child.Properties) > 0 {
+			if s, ok := child.Properties[0].Value.Val.(*protos.Value_StrVal); ok {
+				return s.StrVal
+			}
+		}
+	}
+	return ""
+}
+
+func unmarshalStruct(nodes []*protos.Node, v reflect.Value) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	n := nodes[0]
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		pred := jsonTag(t.Field(i))
+		if pred == "" {
+			continue
+		}
+		if pred == dgraphTypeTag {
+			v.Field(i).SetString(dgraphTypeOf(n))
+			continue
+		}
+		for _, child := range n.Children {
+			if child.Attribute != pred {
+				continue
+			}
+			if err := unmarshalInto([]*protos.Node{child}, v.Field(i).Addr()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
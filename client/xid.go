@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// xidOption is the json tag option that marks a field as holding an external id rather than a
+// literal value, e.g. `json:"author_id,xid"` or `json:"parent_post_id,xid"`.
+const xidOption = "xid"
+
+// xidIndexPredicate is the predicate SetObject uses to index every node it creates on behalf
+// of an xid, so a later request (or a batched lookup within the same one) can resolve the
+// external id back to the uid the server assigned it.
+const xidIndexPredicate = "xid"
+
+// xidSchema indexes xidIndexPredicate for exact-match lookup, which the eq() query in
+// ResolveXids requires. ensureXidIndex applies it once per DgraphClient.
+const xidSchema = xidIndexPredicate + ": string @index(exact) ."
+
+// ensureXidIndex alters the schema to index xidIndexPredicate, the first time d resolves any
+// xid. Later calls are a no-op: sync.Once doesn't distinguish a prior failure from a prior
+// success, so an alter that errors (e.g. a transient disconnect) isn't retried within the same
+// client's lifetime. That matches how rarely this alteration actually needs to run in
+// practice, but is worth knowing if ResolveXids starts failing with an "unindexed predicate"
+// error from the server: restarting the process (or constructing a fresh DgraphClient) retries
+// it.
+func ensureXidIndex(ctx context.Context, d *DgraphClient) error {
+	var err error
+	d.xidIndexOnce.Do(func() {
+		c := protos.NewDgraphClient(d.conns[0])
+		_, err = c.Run(ctx, &protos.Request{Schema: xidSchema})
+	})
+	return err
+}
+
+// xidRef tracks one external id seen while encoding a request, so ResolveXids can look all of
+// them up in a single batched query instead of one lookup per field.
+type xidRef struct {
+	Value string
+	Blank string
+}
+
+// xidBlank returns the blank node label standing in for value until ResolveXids substitutes
+// the real uid, creating a new entry (and reporting created=true) the first time value is
+// seen in this request. Hashing value keeps the label a well-formed blank node name and makes
+// every reference to the same external id within a request converge on one node.
+func (req *Req) xidBlank(value string) (blank string, created bool) {
+	if req.xids == nil {
+		req.xids = make(map[string]*xidRef)
+	}
+	blank = fmt.Sprintf("_:xid-%x", sha1.Sum([]byte(value)))
+	if _, ok := req.xids[blank]; ok {
+		return blank, false
+	}
+	req.xids[blank] = &xidRef{Value: value, Blank: blank}
+	return blank, true
+}
+
+// ResolveXids looks up the uid already assigned to every external id referenced via an xid
+// tag in this request (if any), in a single query, and substitutes those uids in place of the
+// placeholder blank nodes SetObject created for them. External ids that don't resolve to an
+// existing node are left as blank nodes, so the mutation inserts a new one indexed by
+// xidIndexPredicate for next time. The first call also alters the schema (via ensureXidIndex)
+// so xidIndexPredicate is queryable with eq() at all. DgraphClient.Run calls this
+// automatically before sending the request's mutation.
+func (req *Req) ResolveXids(ctx context.Context, d *DgraphClient) error {
+	if len(req.xids) == 0 {
+		return nil
+	}
+
+	if err := ensureXidIndex(ctx, d); err != nil {
+		return x.Wrapf(err, "ResolveXids: indexing %s", xidIndexPredicate)
+	}
+
+	// eq(predicate, v1, v2, ...) matches any node whose predicate equals one of the listed
+	// values; it's a comma-separated argument list, not a bracketed literal.
+	var values []string
+	for _, ref := range req.xids {
+		values = append(values, fmt.Sprintf("%q", ref.Value))
+	}
+	query := fmt.Sprintf(`{ resolved(func: eq(%s, %s)) { uid %s } }`,
+		xidIndexPredicate, strings.Join(values, ", "), xidIndexPredicate)
+
+	c := protos.NewDgraphClient(d.conns[0])
+	resp, err := c.Run(ctx, &protos.Request{Query: query})
+	if err != nil {
+		return x.Wrapf(err, "ResolveXids")
+	}
+
+	resolved := make(map[string]uint64) // xid value -> uid
+	for _, n := range resp.N {
+		val, ok := scalarChild(n, xidIndexPredicate)
+		if !ok {
+			continue
+		}
+		resolved[val] = n.Uid
+	}
+
+	subst := make(map[string]string) // blank label -> resolved subject
+	for blank, ref := range req.xids {
+		if uid, ok := resolved[ref.Value]; ok {
+			subst[blank] = toSubject(uid)
+		}
+	}
+	if len(subst) == 0 {
+		return nil
+	}
+
+	substituteSubjects(req.gr.Mutation.Set, subst)
+	substituteSubjects(req.gr.Mutation.Del, subst)
+	return nil
+}
+
+func substituteSubjects(nquads []*protos.NQuad, subst map[string]string) {
+	for _, nq := range nquads {
+		if s, ok := subst[nq.Subject]; ok {
+			nq.Subject = s
+		}
+		if s, ok := subst[nq.ObjectId]; ok {
+			nq.ObjectId = s
+		}
+	}
+}
+
+// scalarChild returns the string value of n's child carrying predicate pred, mirroring the
+// lookup client.Unmarshal does when decoding a scalar predicate.
+func scalarChild(n *protos.Node, pred string) (string, bool) {
+	for _, child := range n.Children {
+		if child.Attribute != pred || len(child.Properties) == 0 {
+			continue
+		}
+		if s, ok := child.Properties[0].Value.Val.(*protos.Value_StrVal); ok {
+			return s.StrVal, true
+		}
+	}
+	return "", false
+}
@@ -2,11 +2,32 @@ package query
 
 import (
 	"fmt"
+	"io"
+	"sort"
 
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/x"
 )
 
+// SortMode controls how AssertSorted reacts to an out-of-order protos.List.
+type SortMode int
+
+const (
+	// ModeAssert aborts the process via x.AssertTruef, as AssertSorted always did. This
+	// remains the default so tests keep catching ordering bugs loudly.
+	ModeAssert SortMode = iota
+	// ModeRepair silently sorts and de-duplicates the offending list in place.
+	ModeRepair
+	// ModeLogAndRepair does what ModeRepair does, but also logs the repair via x.Printf so
+	// an operator can tell a broken index snuck a list past indexing.
+	ModeLogAndRepair
+)
+
+// CurrentSortMode is the mode AssertSorted dispatches on. It defaults to ModeAssert to
+// preserve existing test behaviour; production binaries that have been burned by a single bad
+// uid list taking down the whole query path can set it to ModeLogAndRepair at startup.
+var CurrentSortMode = ModeAssert
+
 func DebugSubgraph(sg *SubGraph, indent string) {
 	fmt.Printf("%sAttr=%q\n", indent, sg.Attr)
 	fmt.Printf("%s  SrcUids=%v\n", indent, sg.SrcUIDs)
@@ -16,6 +37,57 @@ func DebugSubgraph(sg *SubGraph, indent string) {
 	}
 }
 
+// DebugSubgraphDOT writes sg to w as a Graphviz DOT graph: one node per SubGraph, labelled
+// with its Attr and the sizes of its uid lists, and one edge per parent-child pair labelled
+// with the child's Attr. This is meant to be piped straight into `dot -Tpng` so an operator
+// can see the shape of a broken SubGraph instead of squinting at a fmt.Printf dump.
+func DebugSubgraphDOT(w io.Writer, sg *SubGraph) error {
+	if _, err := fmt.Fprintln(w, "digraph SubGraph {"); err != nil {
+		return err
+	}
+	if err := writeSubgraphDOT(w, sg, 0); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+func writeSubgraphDOT(w io.Writer, sg *SubGraph, id int) error {
+	if sg == nil {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "  n%d [label=%q];\n", id, fmt.Sprintf("%s\\nSrcUIDs=%d DestUIDs=%d",
+		sg.Attr, len(sg.SrcUIDs.GetUids()), len(sg.DestUIDs.GetUids())))
+	if err != nil {
+		return err
+	}
+	nextID := id + 1
+	for _, ch := range sg.Children {
+		childID := nextID
+		if _, err := fmt.Fprintf(w, "  n%d -> n%d [label=%q];\n", id, childID, ch.Attr); err != nil {
+			return err
+		}
+		if err := writeSubgraphDOT(w, ch, childID); err != nil {
+			return err
+		}
+		nextID = advanceID(ch, childID)
+	}
+	return nil
+}
+
+// advanceID returns the next id to hand out after having numbered sg's whole subtree starting
+// at id, mirroring the traversal order writeSubgraphDOT uses.
+func advanceID(sg *SubGraph, id int) int {
+	next := id + 1
+	for _, ch := range sg.Children {
+		next = advanceID(ch, next)
+	}
+	return next
+}
+
+// AssertSorted checks that every SrcUIDs/DestUIDs list in sg's subtree is sorted, dispatching
+// on CurrentSortMode: ModeAssert aborts the process as before, while ModeRepair and
+// ModeLogAndRepair fix the list in place instead of crashing.
 func AssertSorted(sg *SubGraph) {
 	if sg == nil {
 		return
@@ -31,8 +103,48 @@ func AssertSorted(sg *SubGraph) {
 }
 
 func AssertUidListSorted(pl *protos.List, msg string) {
-	for i := 0; i+1 < len(pl.GetUids()); i++ {
-		x.AssertTruef(pl.Uids[i] < pl.Uids[i+1],
-			"%s list not sorted: %v", msg, pl.GetUids())
+	if isUidListSorted(pl) {
+		return
+	}
+	switch CurrentSortMode {
+	case ModeRepair, ModeLogAndRepair:
+		changed := RepairUidList(pl)
+		if changed && CurrentSortMode == ModeLogAndRepair {
+			x.Printf("query: repaired out-of-order %s list: %v", msg, pl.GetUids())
+		}
+	default:
+		x.AssertTruef(false, "%s list not sorted: %v", msg, pl.GetUids())
+	}
+}
+
+func isUidListSorted(pl *protos.List) bool {
+	uids := pl.GetUids()
+	for i := 0; i+1 < len(uids); i++ {
+		if uids[i] >= uids[i+1] {
+			return false
+		}
+	}
+	return true
+}
+
+// RepairUidList sorts pl's uids and removes duplicates in place, returning whether it had to
+// change anything. A cheap pre-check means the common case (already sorted) costs one pass.
+func RepairUidList(pl *protos.List) (changed bool) {
+	uids := pl.GetUids()
+	if isUidListSorted(pl) {
+		return false
+	}
+	changed = true
+
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+
+	deduped := uids[:0]
+	for i, uid := range uids {
+		if i > 0 && uid == uids[i-1] {
+			continue
+		}
+		deduped = append(deduped, uid)
 	}
+	pl.Uids = deduped
+	return true
 }
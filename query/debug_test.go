@@ -0,0 +1,69 @@
+package query
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+func TestRepairUidListSortsAndDedupes(t *testing.T) {
+	pl := &protos.List{Uids: []uint64{3, 1, 2, 1}}
+	if !RepairUidList(pl) {
+		t.Fatalf("expected RepairUidList to report a change")
+	}
+	want := []uint64{1, 2, 3}
+	if len(pl.Uids) != len(want) {
+		t.Fatalf("got %v, want %v", pl.Uids, want)
+	}
+	for i, uid := range want {
+		if pl.Uids[i] != uid {
+			t.Fatalf("got %v, want %v", pl.Uids, want)
+		}
+	}
+}
+
+func TestRepairUidListNoopWhenAlreadySorted(t *testing.T) {
+	pl := &protos.List{Uids: []uint64{1, 2, 3}}
+	if RepairUidList(pl) {
+		t.Fatalf("expected no change for an already-sorted list")
+	}
+	if len(pl.Uids) != 3 || pl.Uids[0] != 1 || pl.Uids[1] != 2 || pl.Uids[2] != 3 {
+		t.Fatalf("list mutated despite being a no-op: %v", pl.Uids)
+	}
+}
+
+func TestAssertUidListSortedRepairsInLogAndRepairMode(t *testing.T) {
+	orig := CurrentSortMode
+	defer func() { CurrentSortMode = orig }()
+	CurrentSortMode = ModeLogAndRepair
+
+	pl := &protos.List{Uids: []uint64{2, 1}}
+	AssertUidListSorted(pl, "test")
+	if !isUidListSorted(pl) {
+		t.Fatalf("expected list to be repaired in place, got %v", pl.Uids)
+	}
+}
+
+func TestDebugSubgraphDOT(t *testing.T) {
+	sg := &SubGraph{
+		Attr:     "forum",
+		SrcUIDs:  &protos.List{Uids: []uint64{1}},
+		DestUIDs: &protos.List{Uids: []uint64{2, 3}},
+		Children: []*SubGraph{
+			{Attr: "threads", DestUIDs: &protos.List{Uids: []uint64{4}}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := DebugSubgraphDOT(&buf, sg); err != nil {
+		t.Fatalf("DebugSubgraphDOT: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"digraph SubGraph {", "forum", "threads", "n0 -> n1"} {
+		if !bytes.Contains([]byte(out), []byte(want)) {
+			t.Fatalf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
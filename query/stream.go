@@ -0,0 +1,32 @@
+package query
+
+import "github.com/dgraph-io/dgraph/protos"
+
+// StreamChildren converts each of root's children into a protos.Node and passes it to onChild
+// as soon as it's converted, instead of requiring the caller to convert the whole SubGraph (as
+// unmarshalAndPrint-style callers do via a single protos.Response) before handing any of it
+// back. root is expected to already be fully evaluated (e.g. by ProcessGraph): this removes
+// the "build the whole response, then send it" step, not query evaluation time itself. Making
+// evaluation itself incremental would additionally require the executor to report a child as
+// done as soon as its own subtree finishes, which is a larger change than this one.
+func StreamChildren(root *SubGraph, onChild func(*protos.Node) error) error {
+	if root == nil {
+		return nil
+	}
+	for _, ch := range root.Children {
+		if err := onChild(toProtoNode(ch)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toProtoNode renders sg and its subtree into the protos.Node shape client.Unmarshal and
+// client.Decoder already know how to walk: one child per attribute.
+func toProtoNode(sg *SubGraph) *protos.Node {
+	n := &protos.Node{Attribute: sg.Attr}
+	for _, ch := range sg.Children {
+		n.Children = append(n.Children, toProtoNode(ch))
+	}
+	return n
+}
@@ -0,0 +1,67 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+var errStreamChildrenTest = errors.New("stream children test error")
+
+func TestStreamChildrenSendsEachRootChild(t *testing.T) {
+	root := &SubGraph{
+		Attr: "forum",
+		Children: []*SubGraph{
+			{Attr: "name"},
+			{Attr: "threads", Children: []*SubGraph{
+				{Attr: "title"},
+			}},
+		},
+	}
+
+	var got []*protos.Node
+	err := StreamChildren(root, func(n *protos.Node) error {
+		got = append(got, n)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamChildren: %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 root children, got %d", len(got))
+	}
+	if got[0].Attribute != "name" {
+		t.Fatalf("expected first child attribute %q, got %q", "name", got[0].Attribute)
+	}
+	if got[1].Attribute != "threads" || len(got[1].Children) != 1 || got[1].Children[0].Attribute != "title" {
+		t.Fatalf("expected second child to carry its own subtree, got %+v", got[1])
+	}
+}
+
+func TestStreamChildrenPropagatesCallbackError(t *testing.T) {
+	root := &SubGraph{Children: []*SubGraph{{Attr: "a"}, {Attr: "b"}}}
+
+	wantErr := errStreamChildrenTest
+	calls := 0
+	err := StreamChildren(root, func(n *protos.Node) error {
+		calls++
+		return wantErr
+	})
+	if err != wantErr {
+		t.Fatalf("expected callback error to propagate, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected StreamChildren to stop after the first error, got %d calls", calls)
+	}
+}
+
+func TestStreamChildrenNilRoot(t *testing.T) {
+	if err := StreamChildren(nil, func(*protos.Node) error {
+		t.Fatalf("onChild should not be called for a nil root")
+		return nil
+	}); err != nil {
+		t.Fatalf("StreamChildren(nil, ...): %v", err)
+	}
+}
@@ -21,28 +21,48 @@ import (
 
 type Forum struct {
 	Id          uint64   `json:"_uid_,omitempty"`
+	DgraphType  string   `json:"dgraph.type,omitempty"`
 	Name        string   `json:"name,omitempty"`
 	Description string   `json:"description,omitempty"`
 	Threads     []Thread `json:"threads,omitempty"`
 }
 
 type Thread struct {
-	Id uint64 `json:"_uid_,omitempty"`
-	// is an openid-connect id of a subject
-	AuthorId string `json:"author_id,omitempty"`
+	Id         uint64 `json:"_uid_,omitempty"`
+	DgraphType string `json:"dgraph.type,omitempty"`
+	// AuthorId is an openid-connect id of a subject. The xid tag makes it an upsert: the
+	// first time an author is seen, a node is created and indexed on it; every later
+	// reference to the same author_id resolves to that node instead of creating a new one.
+	// The "xid" predicate this relies on is indexed automatically, by the first Run call
+	// below that touches an xid field (client.DgraphClient.Run -> Req.ResolveXids ->
+	// ensureXidIndex) - no separate schema step is required here.
+	AuthorId string `json:"author_id,xid,omitempty"`
 	Title    string `json:"title,omitempty"`
 	Preview  string `json:"preview,omitempty"`
 	Posts    []Post `json:"posts,omitempty"`
 }
 
 type Post struct {
-	Id           uint64 `json:"_uid_,omitempty"`
-	AuthorId     string `json:"author_id,omitempty"`
-	ParentPostId string `json:"parent_post_id,omitempty"`
+	Id         uint64 `json:"_uid_,omitempty"`
+	DgraphType string `json:"dgraph.type,omitempty"`
+	AuthorId   string `json:"author_id,xid,omitempty"`
+	// ParentPostId is an external id resolved the same way AuthorId is: to the node indexed
+	// under that value by the "xid" predicate, created on first use. Post carries no xid of
+	// its own, so that node is not the replied-to Post node itself.
+	ParentPostId string `json:"parent_post_id,xid,omitempty"`
 	Title        string `json:"title,omitempty"`
 	Body         string `json:"body,omitempty"`
 }
 
+func init() {
+	// Registering the concrete types lets client.Unmarshal route a type(...) filtered,
+	// polymorphic result array (e.g. expand(_all_) over mixed nodes) back into the right
+	// Go struct, keyed by the dgraph.type value set by SetObject.
+	client.RegisterType("Forum", Forum{})
+	client.RegisterType("Thread", Thread{})
+	client.RegisterType("Post", Post{})
+}
+
 func checkErr(err error) {
 	if err != nil {
 		log.Fatal(err)
@@ -81,21 +101,25 @@ func main() {
 	// The forum/thread/post don't set the Id for the objects. So a new forum would be created
 	// with a thread and posts.
 	f := Forum{
+		DgraphType:  "Forum",
 		Name:        "My forum",
 		Description: "Forum Description",
 		Threads: []Thread{
 			Thread{
-				AuthorId: "author",
-				Title:    "How to build an App?",
-				Preview:  "This is how you do it.",
+				DgraphType: "Thread",
+				AuthorId:   "author",
+				Title:      "How to build an App?",
+				Preview:    "This is how you do it.",
 				Posts: []Post{
 					Post{
-						AuthorId: "author",
-						Title:    "Using the Go client",
+						DgraphType: "Post",
+						AuthorId:   "author",
+						Title:      "Using the Go client",
 					},
 					Post{
-						AuthorId: "author-2",
-						Title:    "Using the HTTP API",
+						DgraphType: "Post",
+						AuthorId:   "author-2",
+						Title:      "Using the HTTP API",
 					},
 				},
 			},
@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// subtreeStore is the slice of the posting-list store that DeleteSubtree needs. It's kept
+// narrow and interface-based so the BFS below can be exercised without a real store.
+type subtreeStore interface {
+	// NewTransaction starts a transaction that both the BFS reads and the DeleteNode calls
+	// are scoped to, so the set of edges walked can't change out from under the deletes a
+	// concurrent mutation is computing against.
+	NewTransaction() subtreeTxn
+}
+
+// subtreeTxn is the transaction handle subtreeStore hands out; DeleteSubtree reads every edge
+// it walks and deletes every node it visits through the same one, committing it once at the
+// end, or discarding it on error.
+type subtreeTxn interface {
+	// Edges returns the uids reachable from uid by following any of predicates, as of this
+	// transaction's view of the store.
+	Edges(uid uint64, predicates []string) ([]uint64, error)
+	// DeleteNode deletes uid and its outgoing edges within the transaction.
+	DeleteNode(uid uint64) error
+	Commit(ctx context.Context) error
+	Discard()
+}
+
+// DeleteSubtree implements the server side of client.DgraphClient.DeleteSubtree: a BFS from
+// req.RootUid over req.Predicates, deleting every reachable node (and the root) inside a
+// single transaction. Cycles are handled by tracking visited uids, so a graph that loops
+// back on itself is still deleted exactly once per node.
+func (w *grpcWorker) DeleteSubtree(ctx context.Context, req *protos.DeleteSubtreeRequest) (*protos.DeleteSubtreeResponse, error) {
+	if req.RootUid == 0 {
+		return nil, x.Errorf("DeleteSubtree: root_uid must be set")
+	}
+	maxDepth := req.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = defaultMaxDeleteDepth
+	}
+
+	txn := w.store.NewTransaction()
+	defer txn.Discard()
+
+	visited := map[uint64]bool{req.RootUid: true}
+	order := []uint64{req.RootUid}
+	queue := []uint64{req.RootUid}
+	var deleted []uint64
+
+	for depth := uint32(0); len(queue) > 0 && depth < maxDepth; depth++ {
+		var next []uint64
+		for _, uid := range queue {
+			children, err := txn.Edges(uid, req.Predicates)
+			if err != nil {
+				return nil, x.Wrapf(err, "DeleteSubtree: reading edges for %#x", uid)
+			}
+			for _, child := range children {
+				if visited[child] {
+					continue
+				}
+				visited[child] = true
+				order = append(order, child)
+				next = append(next, child)
+			}
+		}
+		queue = next
+	}
+
+	// Delete leaves-to-root isn't required for correctness since every delete is scoped to a
+	// single node's own edges; order only matters for minimizing transaction conflicts, so we
+	// delete in visit order.
+	for _, uid := range order {
+		if err := txn.DeleteNode(uid); err != nil {
+			return nil, x.Wrapf(err, "DeleteSubtree: deleting %#x", uid)
+		}
+		deleted = append(deleted, uid)
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		return nil, x.Wrapf(err, "DeleteSubtree: commit")
+	}
+	return &protos.DeleteSubtreeResponse{DeletedUids: deleted}, nil
+}
+
+// defaultMaxDeleteDepth mirrors the client-side default in client.DeleteSubtree.
+const defaultMaxDeleteDepth = 1000
+
+// deleteSubtreeHTTPRequest is the JSON body accepted by HandleDeleteSubtree, for clients that
+// can't use the gRPC API directly.
+type deleteSubtreeHTTPRequest struct {
+	RootUID    string   `json:"root_uid"`
+	Predicates []string `json:"predicates"`
+	MaxDepth   uint32   `json:"max_depth,omitempty"`
+}
+
+// RegisterHTTPHandlers wires w's HTTP verbs onto mux. It's exported rather than run from an
+// init() because, unlike the gRPC verbs (registered on the single protos.Dgraph server), the
+// HTTP surface is assembled by the caller that owns the *http.ServeMux (e.g. cmd/dgraph's
+// server setup) alongside /query, /mutate and the rest of the REST API.
+func (w *grpcWorker) RegisterHTTPHandlers(mux *http.ServeMux) {
+	mux.HandleFunc("/delete_subtree", w.HandleDeleteSubtree)
+}
+
+// HandleDeleteSubtree is the HTTP counterpart of the DeleteSubtree gRPC verb. Callers must
+// route POST /delete_subtree to it themselves, e.g. via RegisterHTTPHandlers, so non-Go
+// clients get the same cascading delete.
+func (w *grpcWorker) HandleDeleteSubtree(rw http.ResponseWriter, r *http.Request) {
+	var hreq deleteSubtreeHTTPRequest
+	if err := json.NewDecoder(r.Body).Decode(&hreq); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rootUID, err := strconv.ParseUint(hreq.RootUID, 0, 64)
+	if err != nil {
+		http.Error(rw, "invalid root_uid: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := w.DeleteSubtree(r.Context(), &protos.DeleteSubtreeRequest{
+		RootUid:    rootUID,
+		Predicates: hreq.Predicates,
+		MaxDepth:   hreq.MaxDepth,
+	})
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(resp)
+}
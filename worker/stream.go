@@ -0,0 +1,28 @@
+package worker
+
+import (
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/query"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// RunStream is the server side of client.DgraphClient.RunStream. It parses and evaluates
+// req.Query the same way Run does (w.evaluate backs both) - so this doesn't reduce evaluation
+// time or server-side memory - then passes the resulting, already-complete SubGraph to
+// query.StreamChildren so each root child is sent to the client as its own StreamResponse
+// instead of all at once as one protos.Response. The benefit is entirely client-side: see
+// query.StreamChildren and client.DgraphClient.RunStream for what that buys the caller.
+func (w *grpcWorker) RunStream(req *protos.Request, stream protos.Dgraph_RunStreamServer) error {
+	root, err := w.evaluate(req.Query)
+	if err != nil {
+		return x.Wrapf(err, "RunStream")
+	}
+
+	err = query.StreamChildren(root, func(n *protos.Node) error {
+		return stream.Send(&protos.StreamResponse{N: n})
+	})
+	if err != nil {
+		return x.Wrapf(err, "RunStream")
+	}
+	return stream.Send(&protos.StreamResponse{Done: true})
+}